@@ -0,0 +1,19 @@
+package cli
+
+// Cli is the top level entry point: it *is* the app's root Cmd (every
+// BoolOpt/StringArg/Command/... method is usable directly on the value App
+// returns) plus the handful of things that only make sense once, for the
+// whole app, such as the loaded config file tree.
+type Cli struct {
+	*Cmd
+
+	configTree map[string]interface{}
+}
+
+// App creates the top level Cli for an application called name, described
+// by desc in help output.
+func App(name, desc string) *Cli {
+	app := &Cli{Cmd: &Cmd{Name: name, Desc: desc}}
+	installCompletionFlags(app.Cmd)
+	return app
+}
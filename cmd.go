@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CmdInitializer is the function passed to Command to configure a
+// sub-command: it receives the freshly created *Cmd and is expected to
+// declare that command's options/arguments/sub-commands and set its
+// Action.
+type CmdInitializer func(*Cmd)
+
+// OptExtra carries the optional, less commonly used settings for an
+// option, passed as the last argument to the (Bool|String|Int|Strings|
+// Ints)Opt family of constructors. A nil *OptExtra is equivalent to an
+// empty one.
+type OptExtra struct {
+	// EnvVar is a space separated list of environment variables used to
+	// initialize the option's value, in order, the first one that is set
+	// winning.
+	EnvVar string
+	// Hidden excludes the option from generated help/usage/completion
+	// output, while still accepting it on the command line.
+	Hidden bool
+	// ConfigKey, if set, is the (nested-command-relative) key this option
+	// resolves its value from in the file loaded via Cli.ConfigFile, when
+	// neither an explicit flag nor EnvVar provided one. See ConfigFile's
+	// doc comment for the full resolution order and how nested commands
+	// map to dotted keys.
+	ConfigKey string
+	// Completer, if set, is invoked by CompleteToken (the hidden
+	// --complete-token runtime mode) to offer dynamic completions for this
+	// option's value; scripts generated with GenerateCompletion do not call
+	// it.
+	Completer Completer
+}
+
+// ArgExtra is the argument equivalent of OptExtra.
+type ArgExtra struct {
+	EnvVar    string
+	Hidden    bool
+	ConfigKey string
+	Completer Completer
+}
+
+// Cmd represents an app or a (possibly nested) command: App returns the
+// root one, and Cmd.Command registers children under it.
+type Cmd struct {
+	// Name is the command's name, as typed by the user on the command
+	// line to select it (irrelevant for the app's root Cmd).
+	Name string
+	// Desc is the one-line description shown in command listings.
+	Desc string
+	// LongDesc is the longer, free-form description shown in this
+	// command's own help output.
+	LongDesc string
+	// Spec is the usage spec string; see the package doc comment for its
+	// grammar. Left empty, it is auto-generated from the declared options
+	// and arguments.
+	Spec string
+
+	// Action is called when this command is the one selected by parse,
+	// unless ActionContext is also set, in which case that takes
+	// precedence.
+	Action func()
+	// ActionContext is the context-aware, cancellable alternative to
+	// Action.
+	ActionContext ActionContext
+
+	// Commands holds the sub-commands registered with Command, in
+	// declaration order.
+	Commands []*Cmd
+	// Options holds the options declared on this command (not including
+	// its sub-commands'), in declaration order.
+	Options []*opt
+	// Args holds the arguments declared on this command, in declaration
+	// order.
+	Args []*arg
+
+	parent *Cmd
+
+	// stringMapSyncs and varSyncs hold the deferred conversions StringMapOpt/
+	// StringMapArg and VarOpt/VarArg/VarOptText/VarArgText register (see
+	// syncStringMaps and syncVars) - they live on the Cmd they were declared
+	// against rather than a package-level registry so two independently
+	// built *Cli trees never share or race on this bookkeeping, and it's
+	// freed along with the Cmd.
+	stringMapSyncs []func() error
+	varSyncs       []func() error
+
+	generateCompletionFlag *string
+	completeTokenFlag      *string
+}
+
+// Command registers a named sub-command of c. init is called immediately
+// with the new *Cmd so it can declare its own options/arguments/
+// sub-commands and set its Action.
+func (c *Cmd) Command(name, desc string, init CmdInitializer) {
+	sub := &Cmd{Name: name, Desc: desc, parent: c}
+	init(sub)
+	c.Commands = append(c.Commands, sub)
+}
+
+// path returns the full, space separated invocation path of c, e.g.
+// "docker run" for the "run" sub-command of the "docker" app.
+func (c *Cmd) path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+func (c *Cmd) findOpt(name string) *opt {
+	for _, o := range c.Options {
+		for _, n := range o.names {
+			if n == name {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cmd) findCommand(name string) *Cmd {
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (c *Cmd) addOpt(names, desc string, extra *OptExtra, value optValue) *opt {
+	if extra == nil {
+		extra = &OptExtra{}
+	}
+	o := &opt{names: strings.Fields(names), desc: desc, extra: extra, value: value, source: "default"}
+	if extra.EnvVar != "" {
+		for _, name := range strings.Fields(extra.EnvVar) {
+			if v, ok := os.LookupEnv(name); ok {
+				value.set(v)
+				o.envResolved = true
+				o.source = "env"
+				break
+			}
+		}
+	}
+	c.Options = append(c.Options, o)
+	return o
+}
+
+// lastOpt returns the option most recently appended to c.Options, letting
+// thin wrappers around addOpt (StringMapOpt, VarOpt, ...) that don't get
+// the *opt back from the underlying BoolOpt/StringOpt/... constructor they
+// delegate to still tag it with a metaVar.
+func (c *Cmd) lastOpt() *opt {
+	return c.Options[len(c.Options)-1]
+}
+
+// lastArg is the argument equivalent of lastOpt.
+func (c *Cmd) lastArg() *arg {
+	return c.Args[len(c.Args)-1]
+}
+
+func (c *Cmd) addArg(name, desc string, extra *ArgExtra, value optValue) *arg {
+	if extra == nil {
+		extra = &ArgExtra{}
+	}
+	a := &arg{name: name, desc: desc, extra: extra, value: value, source: "default"}
+	if extra.EnvVar != "" {
+		for _, name := range strings.Fields(extra.EnvVar) {
+			if v, ok := os.LookupEnv(name); ok {
+				value.set(v)
+				a.envResolved = true
+				a.source = "env"
+				break
+			}
+		}
+	}
+	c.Args = append(c.Args, a)
+	return a
+}
+
+// BoolOpt declares a boolean option, settable without a value (-f or
+// --force), and returns a pointer populated once parse has run.
+func (c *Cmd) BoolOpt(names string, def bool, desc string, extra *OptExtra) *bool {
+	p := new(bool)
+	*p = def
+	c.addOpt(names, desc, extra, &boolValue{p})
+	return p
+}
+
+// StringOpt declares a string-valued option.
+func (c *Cmd) StringOpt(names string, def string, desc string, extra *OptExtra) *string {
+	p := new(string)
+	*p = def
+	c.addOpt(names, desc, extra, &stringValue{p})
+	return p
+}
+
+// IntOpt declares an int-valued option.
+func (c *Cmd) IntOpt(names string, def int, desc string, extra *OptExtra) *int {
+	p := new(int)
+	*p = def
+	c.addOpt(names, desc, extra, &intValue{p})
+	return p
+}
+
+// StringsOpt declares a string-slice-valued option: each repetition of the
+// flag on the command line appends to the slice.
+func (c *Cmd) StringsOpt(names string, def []string, desc string, extra *OptExtra) *[]string {
+	p := new([]string)
+	*p = append([]string{}, def...)
+	c.addOpt(names, desc, extra, &stringsValue{p})
+	return p
+}
+
+// IntsOpt declares an int-slice-valued option.
+func (c *Cmd) IntsOpt(names string, def []int, desc string, extra *OptExtra) *[]int {
+	p := new([]int)
+	*p = append([]int{}, def...)
+	c.addOpt(names, desc, extra, &intsValue{p})
+	return p
+}
+
+// StringArg declares a string-valued argument.
+func (c *Cmd) StringArg(name string, def string, desc string, extra *ArgExtra) *string {
+	p := new(string)
+	*p = def
+	c.addArg(name, desc, extra, &stringValue{p})
+	return p
+}
+
+// IntArg declares an int-valued argument.
+func (c *Cmd) IntArg(name string, def int, desc string, extra *ArgExtra) *int {
+	p := new(int)
+	*p = def
+	c.addArg(name, desc, extra, &intValue{p})
+	return p
+}
+
+// StringsArg declares a string-slice-valued argument: if it is the last
+// declared argument, it greedily consumes every remaining positional
+// token; declared anywhere else, it takes exactly one token, so an
+// argument list shaped like "SRC... DST" is not matched.
+func (c *Cmd) StringsArg(name string, def []string, desc string, extra *ArgExtra) *[]string {
+	p := new([]string)
+	*p = append([]string{}, def...)
+	c.addArg(name, desc, extra, &stringsValue{p})
+	return p
+}
+
+// opt is the internal representation of a declared option.
+type opt struct {
+	names       []string
+	desc        string
+	extra       *OptExtra
+	value       optValue
+	envResolved bool
+
+	// source tracks where the option's current value actually came from,
+	// for PrintHelp's "effective source" column: "default" until one of
+	// addOpt (env), applyConfigValues (config) or consumeOption (flag)
+	// overrides it, each taking precedence over the last.
+	source string
+	// metaVar, if set, is the placeholder PrintHelp shows after the "=" in
+	// the option's usage column, in place of the uppercased first long (or,
+	// lacking one, short) name, e.g. "KEY=VALUE" for a StringMapOpt. See
+	// Placeholder.
+	metaVar string
+}
+
+// arg is the internal representation of a declared argument.
+type arg struct {
+	name        string
+	desc        string
+	extra       *ArgExtra
+	value       optValue
+	envResolved bool
+
+	source  string
+	metaVar string
+}
+
+// optValue is the minimal contract needed to assign a raw command line
+// token (or, for booleans, mere presence) into the pointer an Opt/Arg
+// constructor handed back.
+type optValue interface {
+	set(raw string) error
+	isBool() bool
+}
+
+type boolValue struct{ p *bool }
+
+func (v *boolValue) set(string) error { *v.p = true; return nil }
+func (v *boolValue) isBool() bool     { return true }
+
+type stringValue struct{ p *string }
+
+func (v *stringValue) set(s string) error { *v.p = s; return nil }
+func (v *stringValue) isBool() bool       { return false }
+
+type intValue struct{ p *int }
+
+func (v *intValue) set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid integer value %q", s)
+	}
+	*v.p = n
+	return nil
+}
+func (v *intValue) isBool() bool { return false }
+
+type stringsValue struct{ p *[]string }
+
+func (v *stringsValue) set(s string) error { *v.p = append(*v.p, s); return nil }
+func (v *stringsValue) isBool() bool       { return false }
+
+type intsValue struct{ p *[]int }
+
+func (v *intsValue) set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid integer value %q", s)
+	}
+	*v.p = append(*v.p, n)
+	return nil
+}
+func (v *intsValue) isBool() bool { return false }
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GenerateCompletion and the hidden --generate-completion=<shell>/
+// --complete-token flags let a generated shell script enumerate an app's
+// sub-commands, options and arguments without shelling back out to the Go
+// binary for every keystroke: the static script is produced once (by
+// GenerateCompletion, typically from a "completion" sub-command) by walking
+// the same Options/Args/Commands the parser itself uses. The generated
+// scripts hold only those static word lists and never invoke the binary;
+// Completer hooks are reached only through CompleteToken, i.e. by running
+// the binary with --complete-token directly.
+
+// Completer, when set on an OptExtra or ArgExtra, is invoked by
+// CompleteToken (the hidden --complete-token runtime mode) to offer dynamic
+// completions for that option's or argument's value. The scripts written by
+// GenerateCompletion do not call it. prefix is the partial token the user
+// has typed so far.
+type Completer func(prefix string) []string
+
+// installCompletionFlags registers the hidden --generate-completion=<shell>
+// and --complete-token flags App wires up on every app's root Cmd: Hidden
+// keeps them out of generated help/usage/completion output while still
+// accepting them on the command line, the same way any other Hidden option
+// would be.
+func installCompletionFlags(c *Cmd) {
+	c.generateCompletionFlag = c.StringOpt("generate-completion", "", "generate a completion script for bash, zsh or fish", &OptExtra{Hidden: true})
+	c.completeTokenFlag = c.StringOpt("complete-token", "", "internal: print completions for the token at this index in the remaining arguments", &OptExtra{Hidden: true})
+}
+
+// handleCompletionFlags checks whether args requested one of the hidden
+// --generate-completion/--complete-token modes before a normal parse+
+// dispatch is attempted, since both short circuit the rest of app.run:
+// handled reports whether one of them did, in which case err is app.run's
+// final return value.
+func (app *Cli) handleCompletionFlags(args []string, w io.Writer) (handled bool, err error) {
+	for i, tok := range args {
+		switch {
+		case strings.HasPrefix(tok, "--generate-completion="):
+			return true, app.GenerateCompletion(strings.TrimPrefix(tok, "--generate-completion="), w)
+		case tok == "--complete-token":
+			if i+1 >= len(args) {
+				return true, fmt.Errorf("--complete-token requires an index argument")
+			}
+			idx, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return true, fmt.Errorf("--complete-token index %q is not a number", args[i+1])
+			}
+			rest := append(append([]string{}, args[:i]...), args[i+2:]...)
+			for _, word := range app.CompleteToken(rest, idx) {
+				fmt.Fprintln(w, word)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CompleteToken implements the hidden --complete-token runtime mode (the
+// scripts GenerateCompletion writes do not call it): given the argv typed so far and the index
+// of the token being completed, it walks the command tree the same way
+// GenerateCompletion does and returns suggestions (sub-command names,
+// option flags, argument placeholders) for that position.
+//
+// If the token immediately before cur is the name of an option that has an
+// OptExtra.Completer (or, for the last declared argument, an
+// ArgExtra.Completer), that hook is called with the partial token at cur and
+// its result is returned as-is instead of the static suggestions, so dynamic
+// values (branch names, running container ids, ...) can be offered the same
+// way static ones are.
+func (app *Cli) CompleteToken(argv []string, cur int) []string {
+	node := app.Cmd
+	path := node.Name
+	for i := 0; i < cur && i < len(argv); i++ {
+		matched := false
+		for _, sub := range node.Commands {
+			if sub.Name == argv[i] {
+				node = sub
+				path += " " + sub.Name
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			break
+		}
+	}
+
+	prefix := ""
+	if cur >= 0 && cur < len(argv) {
+		prefix = argv[cur]
+	}
+	if cur > 0 && cur-1 < len(argv) {
+		prev := strings.TrimLeft(argv[cur-1], "-")
+		if o := node.findOpt(prev); o != nil && o.extra.Completer != nil {
+			return o.extra.Completer(prefix)
+		}
+	}
+	if len(node.Args) > 0 {
+		if a := node.Args[len(node.Args)-1]; a.extra.Completer != nil {
+			return a.extra.Completer(prefix)
+		}
+	}
+
+	n := buildCompletionNode(path, node)
+	words := append(childNames(n), n.options...)
+	words = append(words, n.args...)
+	return words
+}
+
+// completionNode is the information the script generator needs from each
+// node (app or command) of the command tree, extracted directly from the
+// declared Options/Args/Commands rather than re-deriving them from Spec,
+// which is optional and, for most apps, never set.
+type completionNode struct {
+	name     string
+	path     string
+	options  []string
+	args     []string
+	children []*completionNode
+}
+
+func buildCompletionNode(path string, c *Cmd) *completionNode {
+	n := &completionNode{name: c.Name, path: path}
+	for _, o := range c.Options {
+		if o.extra.Hidden {
+			continue
+		}
+		for _, name := range o.names {
+			if len(name) == 1 {
+				n.options = append(n.options, "-"+name)
+			} else {
+				n.options = append(n.options, "--"+name)
+			}
+		}
+	}
+	for _, a := range c.Args {
+		n.args = append(n.args, a.name)
+	}
+	sort.Strings(n.options)
+	for _, sub := range c.Commands {
+		n.children = append(n.children, buildCompletionNode(path+" "+sub.Name, sub))
+	}
+	return n
+}
+
+func flattenCompletionNodes(n *completionNode, out *[]*completionNode) {
+	*out = append(*out, n)
+	for _, child := range n.children {
+		flattenCompletionNodes(child, out)
+	}
+}
+
+// GenerateCompletion writes a static completion script for shell ("bash",
+// "zsh" or "fish") to w. The script's word lists are taken from the same
+// command tree mow.cli uses to parse the command line, at the time
+// GenerateCompletion runs; the script is static and has to be generated
+// again after the app's commands or options change. The zsh script
+// completes only the app's top level options: its per-command word lists
+// are written as comments only.
+func (app *Cli) GenerateCompletion(shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return writeBashCompletion(app.Cmd, w)
+	case "zsh":
+		return writeZshCompletion(app.Cmd, w)
+	case "fish":
+		return writeFishCompletion(app.Cmd, w)
+	default:
+		return fmt.Errorf("cli: unsupported completion shell %q (want bash, zsh or fish)", shell)
+	}
+}
+
+func writeBashCompletion(root *Cmd, w io.Writer) error {
+	top := buildCompletionNode(root.Name, root)
+	var nodes []*completionNode
+	flattenCompletionNodes(top, &nodes)
+
+	fmt.Fprintf(w, "# bash completion for %s, generated by mow.cli\n", root.Name)
+	fmt.Fprintf(w, "_%s() {\n", root.Name)
+	fmt.Fprintln(w, "  local cur=\"${COMP_WORDS[COMP_CWORD]}\"")
+	fmt.Fprintln(w, "  local line=\"${COMP_WORDS[*]:1:COMP_CWORD-1}\"")
+	fmt.Fprintln(w, "  case \"$line\" in")
+	for _, n := range nodes {
+		rest := strings.TrimSpace(strings.TrimPrefix(n.path, root.Name))
+		words := append(childNames(n), n.options...)
+		words = append(words, n.args...)
+		fmt.Fprintf(w, "    \"%s\")\n", rest)
+		fmt.Fprintf(w, "      COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(words, " "))
+		fmt.Fprintln(w, "      ;;")
+	}
+	fmt.Fprintln(w, "  esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -F _%s %s\n", root.Name, root.Name)
+	return nil
+}
+
+func writeZshCompletion(root *Cmd, w io.Writer) error {
+	top := buildCompletionNode(root.Name, root)
+	var nodes []*completionNode
+	flattenCompletionNodes(top, &nodes)
+
+	fmt.Fprintf(w, "#compdef %s\n", root.Name)
+	fmt.Fprintf(w, "# zsh completion for %s, generated by mow.cli\n", root.Name)
+	for _, n := range nodes {
+		words := append(childNames(n), n.options...)
+		words = append(words, n.args...)
+		fmt.Fprintf(w, "# %s: %s\n", n.path, strings.Join(words, " "))
+	}
+	fmt.Fprintf(w, "_%s() {\n", root.Name)
+	fmt.Fprintln(w, "  _arguments -C \\")
+	for _, opt := range top.options {
+		fmt.Fprintf(w, "    '%s[%s option]' \\\n", opt, strings.TrimLeft(opt, "-"))
+	}
+	fmt.Fprintln(w, "    '*::arg:->args'")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "compdef _%s %s\n", root.Name, root.Name)
+	return nil
+}
+
+func writeFishCompletion(root *Cmd, w io.Writer) error {
+	top := buildCompletionNode(root.Name, root)
+	var nodes []*completionNode
+	flattenCompletionNodes(top, &nodes)
+
+	fmt.Fprintf(w, "# fish completion for %s, generated by mow.cli\n", root.Name)
+	for _, n := range nodes {
+		rest := strings.TrimSpace(strings.TrimPrefix(n.path, root.Name))
+		condition := fmt.Sprintf("__fish_%s_using_command %s", root.Name, rest)
+		for _, child := range n.children {
+			fmt.Fprintf(w, "complete -c %s -n '%s' -a '%s'\n", root.Name, condition, child.name)
+		}
+		for _, opt := range n.options {
+			fmt.Fprintf(w, "complete -c %s -n '%s' -o '%s'\n", root.Name, condition, strings.TrimLeft(opt, "-"))
+		}
+		for _, arg := range n.args {
+			fmt.Fprintf(w, "complete -c %s -n '%s' -a '%s' -f\n", root.Name, condition, arg)
+		}
+	}
+	return nil
+}
+
+func childNames(n *completionNode) []string {
+	names := make([]string, 0, len(n.children))
+	for _, c := range n.children {
+		names = append(names, c.name)
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCompletionDerivesFromOptionsAndArgs(t *testing.T) {
+	app := App("x", "test")
+	app.BoolOpt("v verbose", false, "", nil)
+	app.StringOpt("o output", "", "", nil)
+	app.Command("sub", "", func(cmd *Cmd) {
+		cmd.StringArg("TARGET", "", "", nil)
+	})
+
+	var buf bytes.Buffer
+	if err := app.GenerateCompletion("bash", &buf); err != nil {
+		t.Fatalf("GenerateCompletion: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"--verbose", "--output", "-v", "-o", "sub"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("bash completion script missing %q:\n%s", want, out)
+		}
+	}
+
+	var subBuf bytes.Buffer
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "TARGET") {
+			subBuf.WriteString(line)
+		}
+	}
+	if subBuf.Len() == 0 {
+		t.Errorf("bash completion script missing sub-command argument TARGET:\n%s", out)
+	}
+}
+
+func TestGenerateCompletionUnsupportedShell(t *testing.T) {
+	app := App("x", "test")
+	if err := app.GenerateCompletion("powershell", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+}
+
+func TestCompleteTokenTopLevel(t *testing.T) {
+	app := App("x", "test")
+	app.BoolOpt("v verbose", false, "", nil)
+	app.Command("sub", "", func(cmd *Cmd) {})
+
+	words := app.CompleteToken([]string{""}, 0)
+	found := map[string]bool{}
+	for _, w := range words {
+		found[w] = true
+	}
+	if !found["sub"] || !found["-v"] {
+		t.Fatalf("CompleteToken = %v, want it to include sub and -v", words)
+	}
+}
+
+func TestCompleteTokenUsesCompleter(t *testing.T) {
+	app := App("x", "test")
+	app.StringOpt("b branch", "", "", &OptExtra{Completer: func(prefix string) []string {
+		return []string{"main", "develop"}
+	}})
+
+	words := app.CompleteToken([]string{"-b", "m"}, 1)
+	if len(words) != 2 || words[0] != "main" || words[1] != "develop" {
+		t.Fatalf("CompleteToken = %v, want Completer output", words)
+	}
+}
+
+func TestHandleCompletionFlagsGenerateCompletion(t *testing.T) {
+	app := App("x", "test")
+	var buf bytes.Buffer
+	handled, err := app.handleCompletionFlags([]string{"--generate-completion=bash"}, &buf)
+	if !handled || err != nil {
+		t.Fatalf("handleCompletionFlags: handled=%v err=%v", handled, err)
+	}
+	if !strings.Contains(buf.String(), "bash completion") {
+		t.Fatalf("expected a bash completion script, got:\n%s", buf.String())
+	}
+}
+
+func TestHandleCompletionFlagsCompleteToken(t *testing.T) {
+	app := App("x", "test")
+	app.Command("sub", "", func(cmd *Cmd) {})
+	var buf bytes.Buffer
+	handled, err := app.handleCompletionFlags([]string{"--complete-token", "0"}, &buf)
+	if !handled || err != nil {
+		t.Fatalf("handleCompletionFlags: handled=%v err=%v", handled, err)
+	}
+	if !strings.Contains(buf.String(), "sub") {
+		t.Fatalf("expected sub in completion output, got %q", buf.String())
+	}
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigFormat names one of the config file formats App.ConfigFile
+// understands.
+type ConfigFormat string
+
+const (
+	ConfigJSON ConfigFormat = "json"
+	ConfigYAML ConfigFormat = "yaml"
+	ConfigTOML ConfigFormat = "toml"
+)
+
+// ConfigFile loads path (parsed as format - "json", "yaml" or "toml") and
+// resolves it as a third tier in the option/argument value resolution
+// order, below explicit flags and environment variables (see OptExtra.
+// EnvVar) but above an option's declared default:
+//
+//	explicit -x/--xxx flag  >  EnvVar  >  config file (via ConfigKey)  >  default
+//
+// Every option/argument anywhere in app's command tree whose ConfigKey is
+// set is resolved against the loaded file immediately: if EnvVar already
+// supplied a value (o.envResolved), the file is not consulted, since env
+// already outranks it; otherwise, if the key is present in the file, it is
+// assigned the same way a command line flag would be. A later explicit
+// flag on the command line still wins, since parse assigns flag values
+// after ConfigFile has already run.
+//
+// Nested commands are addressed with a dotted key: the "memory" option of
+// the "run" sub-command reads from the "run.memory" entry of the config
+// file. Use ConfigValue to look up a resolved entry directly.
+//
+// The yaml and toml parsers are minimal, hand-rolled subsets (no vendored
+// dependency is pulled in for either): both support flat and one-level-
+// nested "key: value"/"key = value" maps of strings, bools and numbers
+// good enough for ConfigKey resolution, not arbitrary YAML/TOML documents
+// (anchors, multi-line strings, arrays of tables, and so on are not
+// recognized). json is parsed with the standard library's encoding/json
+// and has no such restriction.
+func (app *Cli) ConfigFile(path string, format string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cli: reading config file %s: %w", path, err)
+	}
+
+	var tree map[string]interface{}
+	switch ConfigFormat(strings.ToLower(format)) {
+	case ConfigJSON:
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return fmt.Errorf("cli: parsing %s as json: %w", path, err)
+		}
+	case ConfigYAML:
+		tree, err = parseSimpleYAML(data)
+		if err != nil {
+			return fmt.Errorf("cli: parsing %s as yaml: %w", path, err)
+		}
+	case ConfigTOML:
+		tree, err = parseSimpleTOML(data)
+		if err != nil {
+			return fmt.Errorf("cli: parsing %s as toml: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("cli: unsupported config format %q (want json, yaml or toml)", format)
+	}
+
+	app.configTree = tree
+	app.applyConfigValues(app.Cmd, "")
+	return nil
+}
+
+// applyConfigValues resolves ConfigKey for every option/argument declared
+// directly on c, then recurses into c.Commands with prefix extended by each
+// sub-command's name, so "run"'s "memory" option resolves against the
+// "run.memory" file entry.
+func (app *Cli) applyConfigValues(c *Cmd, prefix string) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	for _, o := range c.Options {
+		if o.envResolved || o.extra.ConfigKey == "" {
+			continue
+		}
+		if v, ok := app.ConfigValue(key(o.extra.ConfigKey)); ok {
+			o.value.set(v)
+			o.source = "config"
+		}
+	}
+	for _, a := range c.Args {
+		if a.envResolved || a.extra.ConfigKey == "" {
+			continue
+		}
+		if v, ok := app.ConfigValue(key(a.extra.ConfigKey)); ok {
+			a.value.set(v)
+			a.source = "config"
+		}
+	}
+	for _, sub := range c.Commands {
+		app.applyConfigValues(sub, key(sub.Name))
+	}
+}
+
+// ConfigValue resolves a dotted key (e.g. "run.memory") against the config
+// file loaded with ConfigFile, walking nested objects one path component at
+// a time. It returns false if the file hasn't been loaded or the key isn't
+// present.
+func (app *Cli) ConfigValue(key string) (string, bool) {
+	if app.configTree == nil {
+		return "", false
+	}
+	var cur interface{} = app.configTree
+	for _, part := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return "", false
+		}
+	}
+	switch v := cur.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case nil:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// parseSimpleYAML parses the restricted subset of YAML documented on
+// ConfigFile: a sequence of "key: value" lines, "#" line comments, blank
+// lines ignored, and exactly one level of nesting signalled by a "key:"
+// line (nothing after the colon) followed by more indented "key: value"
+// lines, mapping to a nested map[string]interface{}.
+func parseSimpleYAML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	var nested map[string]interface{}
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+
+		idx := strings.IndexByte(trimmed, ':')
+		if idx < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\", got %q", n+1, trimmed)
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		val := strings.TrimSpace(trimmed[idx+1:])
+
+		if !indented {
+			if val == "" {
+				nested = map[string]interface{}{}
+				root[key] = nested
+				continue
+			}
+			nested = nil
+			root[key] = parseScalar(val)
+			continue
+		}
+		if nested == nil {
+			return nil, fmt.Errorf("line %d: indented entry %q outside of any top level key", n+1, key)
+		}
+		nested[key] = parseScalar(val)
+	}
+	return root, nil
+}
+
+// parseSimpleTOML parses the restricted subset of TOML documented on
+// ConfigFile: "key = value" lines at the top level, "#" line comments,
+// blank lines ignored, and "[section]" headers that group the "key =
+// value" lines following them into a nested map[string]interface{}.
+func parseSimpleTOML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	cur := root
+	for n, raw := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			sub := map[string]interface{}{}
+			root[section] = sub
+			cur = sub
+			continue
+		}
+		idx := strings.IndexByte(trimmed, '=')
+		if idx < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key = value\" or \"[section]\", got %q", n+1, trimmed)
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		val := strings.TrimSpace(trimmed[idx+1:])
+		cur[key] = parseScalar(val)
+	}
+	return root, nil
+}
+
+// parseScalar converts a bare yaml/toml scalar into the same string/bool/
+// float64 shapes encoding/json would hand back for an equivalent JSON
+// value, so ConfigValue doesn't need to care which format a value came
+// from.
+func parseScalar(val string) interface{} {
+	if len(val) >= 2 && (val[0] == '"' && val[len(val)-1] == '"' || val[0] == '\'' && val[len(val)-1] == '\'') {
+		return val[1 : len(val)-1]
+	}
+	if b, err := strconv.ParseBool(val); err == nil {
+		return b
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f
+	}
+	return val
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing temp config: %v", err)
+	}
+	return path
+}
+
+func TestConfigFileResolvesConfigKey(t *testing.T) {
+	path := writeTempConfig(t, "cfg.json", `{"memory": "512m", "run": {"cpus": "2"}}`)
+
+	app := App("x", "test")
+	memory := app.StringOpt("m memory", "", "", &OptExtra{ConfigKey: "memory"})
+	var cpus *string
+	app.Command("run", "", func(cmd *Cmd) {
+		cpus = cmd.StringOpt("cpus", "", "", &OptExtra{ConfigKey: "cpus"})
+	})
+
+	if err := app.ConfigFile(path, "json"); err != nil {
+		t.Fatalf("ConfigFile: %v", err)
+	}
+	if err := app.RunArgs([]string{"run"}); err != nil {
+		t.Fatalf("RunArgs: %v", err)
+	}
+	if *memory != "512m" {
+		t.Fatalf("memory = %q, want %q", *memory, "512m")
+	}
+	if *cpus != "2" {
+		t.Fatalf("cpus = %q, want %q", *cpus, "2")
+	}
+}
+
+func TestConfigFileFlagOverridesConfig(t *testing.T) {
+	path := writeTempConfig(t, "cfg.json", `{"memory": "512m"}`)
+
+	app := App("x", "test")
+	memory := app.StringOpt("m memory", "", "", &OptExtra{ConfigKey: "memory"})
+	if err := app.ConfigFile(path, "json"); err != nil {
+		t.Fatalf("ConfigFile: %v", err)
+	}
+	if err := app.RunArgs([]string{"-m", "1g"}); err != nil {
+		t.Fatalf("RunArgs: %v", err)
+	}
+	if *memory != "1g" {
+		t.Fatalf("memory = %q, want flag value %q", *memory, "1g")
+	}
+}
+
+func TestConfigFileYAML(t *testing.T) {
+	path := writeTempConfig(t, "cfg.yaml", "memory: 512m\nrun:\n  cpus: 2\n")
+
+	app := App("x", "test")
+	memory := app.StringOpt("m memory", "", "", &OptExtra{ConfigKey: "memory"})
+	if err := app.ConfigFile(path, "yaml"); err != nil {
+		t.Fatalf("ConfigFile: %v", err)
+	}
+	if err := app.RunArgs(nil); err != nil {
+		t.Fatalf("RunArgs: %v", err)
+	}
+	if *memory != "512m" {
+		t.Fatalf("memory = %q, want %q", *memory, "512m")
+	}
+	if v, ok := app.ConfigValue("run.cpus"); !ok || v != "2" {
+		t.Fatalf("ConfigValue(run.cpus) = %q, %v", v, ok)
+	}
+}
+
+func TestConfigFileTOML(t *testing.T) {
+	path := writeTempConfig(t, "cfg.toml", "memory = \"512m\"\n\n[run]\ncpus = 2\n")
+
+	app := App("x", "test")
+	if err := app.ConfigFile(path, "toml"); err != nil {
+		t.Fatalf("ConfigFile: %v", err)
+	}
+	if v, ok := app.ConfigValue("memory"); !ok || v != "512m" {
+		t.Fatalf("ConfigValue(memory) = %q, %v", v, ok)
+	}
+	if v, ok := app.ConfigValue("run.cpus"); !ok || v != "2" {
+		t.Fatalf("ConfigValue(run.cpus) = %q, %v", v, ok)
+	}
+}
+
+func TestConfigFileUnsupportedFormat(t *testing.T) {
+	path := writeTempConfig(t, "cfg.ini", "memory=512m\n")
+	app := App("x", "test")
+	if err := app.ConfigFile(path, "ini"); err == nil {
+		t.Fatal("expected an error for an unsupported config format")
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ActionContext is an alternative to Action for apps and commands whose
+// work can be long running and should be cancellable: if set, Run prefers
+// it over Action and calls it with a context that is cancelled as soon as
+// the process receives SIGINT or SIGTERM, mirroring the ctx argument
+// go-getoptions passes to actions registered through InterruptContext.
+//
+// Only one of Action or ActionContext should be set on a given App/Cmd; if
+// both are set, ActionContext takes precedence.
+type ActionContext func(ctx context.Context) error
+
+// dispatchAction runs the Action/ActionContext of the command parse
+// selected, preferring ActionContext when set. ctx comes straight from
+// app.run's caller (Run's signal-cancelled context, or context.Background()
+// for RunArgs/RunString) rather than a package-level variable, so that
+// concurrent or reentrant Run/RunArgs calls - realistic once an app built on
+// RunArgs is embedded in a long running REPL or server - never see a
+// context meant for a different, unrelated invocation.
+func dispatchAction(selected *Cmd, ctx context.Context) error {
+	if selected.ActionContext != nil {
+		return selected.ActionContext(ctx)
+	}
+	if selected.Action != nil {
+		selected.Action()
+	}
+	return nil
+}
+
+func runSignalContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sig)
+	}()
+	return ctx, cancel
+}
+
+// Run parses args (os.Args-shaped, including the program name at index 0)
+// and dispatches to the selected command the same way RunArgs does, except
+// that it additionally:
+//
+//   - installs a context, cancelled on SIGINT/SIGTERM, that is passed to
+//     the deepest selected command's ActionContext (if it has one instead
+//     of a plain Action)
+//   - for existing callers that only check the returned error (or ignore it
+//     entirely, as most code written before this change does), preserves
+//     the historical exit-on-usage-error behaviour: a bad command line
+//     still prints usage and calls os.Exit(1) rather than only returning a
+//     *UsageError, so older programs keep working unchanged. Errors
+//     returned by an Action/ActionContext are new - nothing produced them
+//     before - so those are simply returned rather than exiting, which is
+//     the compatibility-preserving choice.
+func (app *Cli) Run(args []string) error {
+	ctx, cancel := runSignalContext()
+	defer cancel()
+
+	err := app.run(args[1:], ctx)
+	if _, ok := err.(*UsageError); ok {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDispatchActionPrefersActionContext(t *testing.T) {
+	var actionCalled bool
+	wantErr := errors.New("boom")
+	c := &Cmd{
+		Action: func() { actionCalled = true },
+		ActionContext: func(ctx context.Context) error {
+			return wantErr
+		},
+	}
+	if err := dispatchAction(c, context.Background()); err != wantErr {
+		t.Fatalf("dispatchAction error = %v, want %v", err, wantErr)
+	}
+	if actionCalled {
+		t.Fatal("Action should not be called when ActionContext is set")
+	}
+}
+
+func TestDispatchActionFallsBackToAction(t *testing.T) {
+	var actionCalled bool
+	c := &Cmd{Action: func() { actionCalled = true }}
+	if err := dispatchAction(c, context.Background()); err != nil {
+		t.Fatalf("dispatchAction: unexpected error: %v", err)
+	}
+	if !actionCalled {
+		t.Fatal("expected Action to be called")
+	}
+}
+
+func TestRunSignalContextCancelledOnSIGINT(t *testing.T) {
+	ctx, cancel := runSignalContext()
+	defer cancel()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("context was not cancelled after SIGINT")
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PrintHelp writes a usage message for c to w: the usage line with its spec
+// (auto-generated from [OPTIONS] plus the declared arguments, unless Spec
+// was set explicitly), its description and long description, its
+// sub-commands, and its declared options and arguments, in that order. It
+// is meant to be called by an app's own -h/--help handling (mow.cli does
+// not install one itself, and its parser does not interpret Spec for
+// validation either; see parse).
+//
+// Each option/argument line ends with a bracketed source tag - "flag",
+// "env", "config" or "default" - reporting where its current value
+// actually came from, following the same flag > env > config > default
+// precedence ConfigFile documents, since for long-lived or config-heavy
+// apps "what did this option end up being and why" is often the more
+// useful question than "what can I pass on the command line".
+func (c *Cmd) PrintHelp(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s %s\n", c.path(), c.usageSpec())
+	if c.Desc != "" {
+		fmt.Fprintf(w, "\n%s\n", c.Desc)
+	}
+	if c.LongDesc != "" {
+		fmt.Fprintf(w, "\n%s\n", c.LongDesc)
+	}
+
+	if len(c.Commands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		for _, sub := range c.Commands {
+			fmt.Fprintf(w, "  %-20s %s\n", sub.Name, sub.Desc)
+		}
+	}
+
+	if len(c.Options) > 0 {
+		fmt.Fprintln(w, "\nOptions:")
+		for _, o := range c.Options {
+			if o.extra.Hidden {
+				continue
+			}
+			fmt.Fprintf(w, "  %-20s %s [%s]\n", o.usageColumn(), o.desc, o.source)
+		}
+	}
+
+	if len(c.Args) > 0 {
+		fmt.Fprintln(w, "\nArguments:")
+		for _, a := range c.Args {
+			if a.extra.Hidden {
+				continue
+			}
+			fmt.Fprintf(w, "  %-20s %s [%s]\n", a.name, a.desc, a.source)
+		}
+	}
+}
+
+// usageSpec returns c.Spec as-is if it was set explicitly, otherwise the
+// same auto-generated spec documented on the Cmd.Spec field: "[OPTIONS]"
+// (if c has any declared options) followed by the declared argument names,
+// in declaration order.
+func (c *Cmd) usageSpec() string {
+	if c.Spec != "" {
+		return c.Spec
+	}
+	var parts []string
+	if len(c.Options) > 0 {
+		parts = append(parts, "[OPTIONS]")
+	}
+	for _, a := range c.Args {
+		parts = append(parts, a.name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// usageColumn is the left hand side of an option's help line: its names,
+// longest first, joined with ", ", followed by its meta-var (from
+// StringMapOpt's "KEY=VALUE" or a VarOpt value's Placeholder, falling back
+// to the option's own uppercased first long name) unless it's a boolean
+// flag, which takes no value at all.
+func (o *opt) usageColumn() string {
+	names := append([]string{}, o.names...)
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	var flags []string
+	for _, n := range names {
+		if len(n) == 1 {
+			flags = append(flags, "-"+n)
+		} else {
+			flags = append(flags, "--"+n)
+		}
+	}
+	col := strings.Join(flags, ", ")
+	if o.value.isBool() {
+		return col
+	}
+	return col + "=" + o.metaVarOrDefault()
+}
+
+func (o *opt) metaVarOrDefault() string {
+	if o.metaVar != "" {
+		return o.metaVar
+	}
+	for _, n := range o.names {
+		if len(n) > 1 {
+			return strings.ToUpper(n)
+		}
+	}
+	return strings.ToUpper(o.names[0])
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintHelpShowsEffectiveSource(t *testing.T) {
+	t.Setenv("TH_NAME", "bob")
+
+	app := App("x", "test app")
+	app.StringOpt("v verbose", "", "be verbose", nil)
+	app.StringOpt("n name", "", "your name", &OptExtra{EnvVar: "TH_NAME"})
+
+	var buf bytes.Buffer
+	app.PrintHelp(&buf)
+	out := buf.String()
+
+	if !strings.Contains(out, "--verbose, -v") || !strings.Contains(out, "[default]") {
+		t.Fatalf("expected an unresolved option to be tagged [default], got:\n%s", out)
+	}
+	if !strings.Contains(out, "--name, -n") || !strings.Contains(out, "[env]") {
+		t.Fatalf("expected the env-resolved option to be tagged [env], got:\n%s", out)
+	}
+}
+
+func TestPrintHelpFlagSourceOverridesDefault(t *testing.T) {
+	app := App("x", "test app")
+	app.StringOpt("o output", "", "output path", nil)
+	if err := app.RunArgs([]string{"-o", "/tmp/out"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	app.PrintHelp(&buf)
+	if !strings.Contains(buf.String(), "[flag]") {
+		t.Fatalf("expected the explicitly-set option to be tagged [flag], got:\n%s", buf.String())
+	}
+}
+
+func TestPrintHelpStringMapMetaVar(t *testing.T) {
+	app := App("x", "test app")
+	app.StringMapOpt("D", "define a build arg", nil)
+
+	var buf bytes.Buffer
+	app.PrintHelp(&buf)
+	if !strings.Contains(buf.String(), "-D=KEY=VALUE") {
+		t.Fatalf("expected the StringMapOpt meta-var to be KEY=VALUE, got:\n%s", buf.String())
+	}
+}
+
+func TestPrintHelpVarOptPlaceholder(t *testing.T) {
+	app := App("x", "test app")
+	var d durationValue
+	app.VarOpt("t timeout", &fakeDuration{}, "timeout", nil)
+	_ = d
+
+	var buf bytes.Buffer
+	app.PrintHelp(&buf)
+	if !strings.Contains(buf.String(), "--timeout, -t=SECONDS") {
+		t.Fatalf("expected the custom Value's Placeholder to be used as the meta-var, got:\n%s", buf.String())
+	}
+}
+
+// fakeDuration is a minimal Value+Placeholder implementation standing in
+// for a hand-written custom type, to make sure PrintHelp's meta-var isn't
+// hard-wired to durationValue specifically.
+type fakeDuration struct{ s string }
+
+func (f *fakeDuration) Set(s string) error  { f.s = s; return nil }
+func (f *fakeDuration) String() string      { return f.s }
+func (f *fakeDuration) Placeholder() string { return "SECONDS" }
+
+func TestPrintHelpSubCommands(t *testing.T) {
+	app := App("x", "test app")
+	app.Command("run", "run something", func(cmd *Cmd) {})
+
+	var buf bytes.Buffer
+	app.PrintHelp(&buf)
+	if !strings.Contains(buf.String(), "run") || !strings.Contains(buf.String(), "run something") {
+		t.Fatalf("expected the sub-command to be listed, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parse walks args against c's declared options, arguments and
+// sub-commands, assigning values as it goes, and returns the deepest
+// command selected (c itself, if it has no matching sub-command token) or
+// a *UsageError describing what went wrong.
+//
+// Options are recognized anywhere in the remaining token stream (long
+// --name/--name=value/--name value, short -n/-nvalue/-n value, and short
+// boolean folding as in -it for -i -t); the first positional token, before
+// any other positional token has been seen, is tried against this
+// command's sub-commands before falling back to being this command's first
+// argument. A lone "--" stops option parsing and treats everything after
+// it as positional. This is a simpler, declaration-order matcher rather
+// than the full backtracking FSM the Spec grammar in the package doc
+// comment describes - Spec itself is not yet interpreted for validation.
+func (c *Cmd) parse(args []string) (*Cmd, error) {
+	cur := c
+	var positional []string
+
+	i := 0
+	for i < len(args) {
+		tok := args[i]
+		if tok == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(tok) > 1 && strings.HasPrefix(tok, "-") {
+			consumed, err := cur.consumeOption(args, i)
+			if err != nil {
+				return nil, cur.usageError(err)
+			}
+			i += consumed
+			continue
+		}
+		if len(positional) == 0 {
+			if sub := cur.findCommand(tok); sub != nil {
+				cur = sub
+				i++
+				continue
+			}
+		}
+		positional = append(positional, tok)
+		i++
+	}
+
+	if err := cur.fillArgs(positional); err != nil {
+		return nil, cur.usageError(err)
+	}
+	return cur, nil
+}
+
+func (c *Cmd) usageError(err error) *UsageError {
+	return &UsageError{Command: c.path(), Usage: c.usageSpec(), Msg: err.Error()}
+}
+
+// consumeOption handles the option token at args[i] (which starts with at
+// least one dash) and returns how many tokens it consumed.
+func (c *Cmd) consumeOption(args []string, i int) (int, error) {
+	tok := args[i]
+
+	if strings.HasPrefix(tok, "--") {
+		name := tok[2:]
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			val := name[eq+1:]
+			name = name[:eq]
+			o := c.findOpt(name)
+			if o == nil {
+				return 0, fmt.Errorf("unknown option --%s", name)
+			}
+			if err := o.value.set(val); err != nil {
+				return 0, err
+			}
+			o.source = "flag"
+			return 1, nil
+		}
+		o := c.findOpt(name)
+		if o == nil {
+			return 0, fmt.Errorf("unknown option --%s", name)
+		}
+		if o.value.isBool() {
+			o.value.set("true")
+			o.source = "flag"
+			return 1, nil
+		}
+		if i+1 >= len(args) {
+			return 0, fmt.Errorf("option --%s requires a value", name)
+		}
+		if err := o.value.set(args[i+1]); err != nil {
+			return 0, err
+		}
+		o.source = "flag"
+		return 2, nil
+	}
+
+	// Short option(s): -x, -xvalue, -x value, or folded booleans -xyz.
+	rest := tok[1:]
+	consumed := 1
+	for len(rest) > 0 {
+		name := rest[:1]
+		o := c.findOpt(name)
+		if o == nil {
+			return 0, fmt.Errorf("unknown option -%s", name)
+		}
+		rest = rest[1:]
+		if o.value.isBool() {
+			o.value.set("true")
+			o.source = "flag"
+			continue
+		}
+		if len(rest) > 0 {
+			val := strings.TrimPrefix(rest, "=")
+			if err := o.value.set(val); err != nil {
+				return 0, err
+			}
+			o.source = "flag"
+			rest = ""
+			continue
+		}
+		if i+1 >= len(args) {
+			return 0, fmt.Errorf("option -%s requires a value", name)
+		}
+		if err := o.value.set(args[i+1]); err != nil {
+			return 0, err
+		}
+		o.source = "flag"
+		consumed++
+		break
+	}
+	return consumed, nil
+}
+
+// fillArgs assigns positional tokens to c's declared arguments, in order.
+// If the last declared argument is a slice-valued one, it greedily
+// consumes every remaining positional token.
+func (c *Cmd) fillArgs(positional []string) error {
+	for idx, a := range c.Args {
+		if idx == len(c.Args)-1 {
+			if sv, ok := a.value.(*stringsValue); ok {
+				for _, p := range positional {
+					sv.set(p)
+				}
+				if len(positional) > 0 {
+					a.source = "flag"
+				}
+				positional = nil
+				break
+			}
+		}
+		if len(positional) == 0 {
+			return fmt.Errorf("missing argument %s", a.name)
+		}
+		if err := a.value.set(positional[0]); err != nil {
+			return err
+		}
+		a.source = "flag"
+		positional = positional[1:]
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+	return nil
+}
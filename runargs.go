@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UsageError is returned by RunArgs/RunString when the supplied arguments
+// don't match the app or command's declared options, arguments and
+// sub-commands (Run instead prints it and exits). Unlike the historical
+// behaviour of Run, which
+// prints the usage message and calls os.Exit, callers of RunArgs/RunString
+// get a plain error back and can decide for themselves what to do with it -
+// this is what lets mow.cli be embedded in long-running processes such as
+// REPLs, where exiting the process on a bad command line isn't an option.
+type UsageError struct {
+	// Command is the full invocation path (e.g. "docker run") of the
+	// command that rejected the arguments.
+	Command string
+	// Usage is the one-line usage string that would normally be printed.
+	Usage string
+	// Msg is a short, user facing description of what went wrong.
+	Msg string
+}
+
+func (e *UsageError) Error() string {
+	if e.Command != "" {
+		return fmt.Sprintf("%s: %s\nUsage: %s %s", e.Command, e.Msg, e.Command, e.Usage)
+	}
+	return e.Msg
+}
+
+// RunArgs parses args (NOT including the program name - unlike Run, which
+// still expects os.Args) against the app's spec and dispatches to the
+// selected command's Action/ActionContext. It reuses the same parsing
+// machinery Run is built on, without Run's os.Exit-on-usage-error
+// behaviour, so it is safe to call repeatedly from a
+// long running process such as a REPL.
+func (app *Cli) RunArgs(args []string) error {
+	return app.run(args, context.Background())
+}
+
+// run is the shared parse+dispatch path behind both RunArgs (which always
+// dispatches with context.Background()) and Run (which dispatches with a
+// context cancelled on SIGINT/SIGTERM) - ctx is passed straight through to
+// dispatchAction rather than stashed in a package-level variable, so
+// concurrent or reentrant Run/RunArgs calls, including ones against
+// different *Cli trees, never see each other's context.
+func (app *Cli) run(args []string, ctx context.Context) error {
+	if handled, err := app.handleCompletionFlags(args, os.Stdout); handled {
+		return err
+	}
+
+	selected, err := app.Cmd.parse(args)
+	if err != nil {
+		return err
+	}
+	if err := syncStringMaps(app.Cmd); err != nil {
+		return err
+	}
+	if err := syncVars(app.Cmd); err != nil {
+		return err
+	}
+	return dispatchAction(selected, ctx)
+}
+
+// RunString tokenizes line with Tokenize and runs the result through
+// RunArgs. It is meant for interactive shells and REPLs built on top of
+// mow.cli, where a user types a full command line as a single string.
+func (app *Cli) RunString(line string) error {
+	args, err := Tokenize(line)
+	if err != nil {
+		return &UsageError{Command: app.Name, Usage: app.usageSpec(), Msg: err.Error()}
+	}
+	return app.RunArgs(args)
+}
+
+// Tokenize splits line into command line style tokens, the way a POSIX
+// shell would: tokens are separated by unquoted whitespace, single and
+// double quotes group their contents into one token (with, inside double
+// quotes, backslash escaping the characters \, $, ", and the quote
+// character itself treated literally inside single quotes), a backslash
+// outside of quotes escapes the next character, and a lone "--" token is
+// passed through as-is, marking the end of option-like tokens for whatever
+// parses the result (mow.cli's own parser, in RunString's case).
+func Tokenize(line string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	haveToken := false
+
+	runes := []rune(line)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if haveToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				haveToken = false
+			}
+			i++
+		case r == '\'':
+			haveToken = true
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated single-quoted string")
+				}
+				if runes[i] == '\'' {
+					i++
+					break
+				}
+				cur.WriteRune(runes[i])
+				i++
+			}
+		case r == '"':
+			haveToken = true
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated double-quoted string")
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(`\"$`, runes[i+1]) {
+					cur.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				cur.WriteRune(runes[i])
+				i++
+			}
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			haveToken = true
+			cur.WriteRune(runes[i+1])
+			i += 2
+		default:
+			haveToken = true
+			cur.WriteRune(r)
+			i++
+		}
+	}
+	if haveToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
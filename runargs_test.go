@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"plain words", "run --foo bar", []string{"run", "--foo", "bar"}},
+		{"double quoted with space", `run "hello world"`, []string{"run", "hello world"}},
+		{"single quoted literal", `run 'a\b $x "q"'`, []string{"run", `a\b $x "q"`}},
+		{"double quote escapes", `run "a\"b\$c\\d"`, []string{"run", `a"b$c\d`}},
+		{"unquoted backslash escape", `run a\ b`, []string{"run", "a b"}},
+		{"dash dash passthrough", `run -- -x --y`, []string{"run", "--", "-x", "--y"}},
+		{"extra whitespace collapses", "  run   --foo  ", []string{"run", "--foo"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Tokenize(c.line)
+			if err != nil {
+				t.Fatalf("Tokenize(%q): unexpected error: %v", c.line, err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("Tokenize(%q) = %#v, want %#v", c.line, got, c.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	cases := []string{
+		`run 'unterminated`,
+		`run "unterminated`,
+		`run trailing\`,
+	}
+	for _, line := range cases {
+		if _, err := Tokenize(line); err == nil {
+			t.Errorf("Tokenize(%q): expected an error, got none", line)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StringMapOpt declares a map-valued option: each repetition of the flag on
+// the command line (e.g. `-D os=linux -D arch=amd64`) must look like
+// `KEY=VALUE` and is accumulated into the returned map, similar to how
+// StringsOpt accumulates repeated plain values into a slice. PrintHelp
+// shows the option's meta-var as KEY=VALUE rather than a bare value, since
+// that's the shape each occurrence must have.
+func (c *Cmd) StringMapOpt(names, desc string, extra *OptExtra) *map[string]string {
+	raw := c.StringsOpt(names, nil, desc, extra)
+	c.lastOpt().metaVar = "KEY=VALUE"
+	return c.bindStringMap(raw, names)
+}
+
+// StringMapOpt is the App-level equivalent of Cmd.StringMapOpt, for global
+// options declared directly on the app.
+func (app *Cli) StringMapOpt(names, desc string, extra *OptExtra) *map[string]string {
+	return app.Cmd.StringMapOpt(names, desc, extra)
+}
+
+// StringMapArg is the argument equivalent of StringMapOpt: it collects
+// KEY=VALUE arguments into a map. Like StringsArg, it takes every remaining
+// positional token (possibly none) only when it is the last declared
+// argument; declared anywhere else, it takes exactly one.
+func (c *Cmd) StringMapArg(name, desc string, extra *ArgExtra) *map[string]string {
+	raw := c.StringsArg(name, nil, desc, extra)
+	c.lastArg().metaVar = "KEY=VALUE"
+	return c.bindStringMap(raw, name)
+}
+
+// bindStringMap records, on c, the conversion from the raw "KEY=VALUE"
+// strings parse collects (via the existing StringsOpt/StringsArg
+// machinery) into the map a StringMapOpt/StringMapArg caller was handed
+// back. There's no FSM atom of its own for "option with repeatable
+// key=value values" yet, so this piggybacks on the slice one and
+// materializes the map once parsing has finished - see syncStringMaps. The
+// conversion is kept on c.stringMapSyncs rather than a package-level
+// registry so two independently built *Cli trees (e.g. in parallel tests)
+// don't share or race on any state, and so it doesn't outlive the Cmd it
+// belongs to.
+func (c *Cmd) bindStringMap(raw *[]string, label string) *map[string]string {
+	result := &map[string]string{}
+	c.stringMapSyncs = append(c.stringMapSyncs, func() error {
+		m := make(map[string]string, len(*raw))
+		for _, kv := range *raw {
+			idx := strings.IndexByte(kv, '=')
+			if idx < 0 {
+				return fmt.Errorf("invalid value %q for %s: want KEY=VALUE", kv, label)
+			}
+			m[kv[:idx]] = kv[idx+1:]
+		}
+		*result = m
+		return nil
+	})
+	return result
+}
+
+// syncStringMaps materializes every *map[string]string produced by
+// StringMapOpt/StringMapArg anywhere in c's tree, returning the first
+// "want KEY=VALUE" error it hits rather than silently dropping the
+// offending entry. RunArgs calls this right after a successful parse and
+// before dispatching to the selected command's Action/ActionContext, so
+// maps are populated by the time user code runs regardless of which
+// command in the tree declared them.
+func syncStringMaps(c *Cmd) error {
+	for _, sync := range c.stringMapSyncs {
+		if err := sync(); err != nil {
+			return c.usageError(err)
+		}
+	}
+	for _, sub := range c.Commands {
+		if err := syncStringMaps(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
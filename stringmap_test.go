@@ -0,0 +1,51 @@
+package cli
+
+import "testing"
+
+func TestStringMapOptAccumulates(t *testing.T) {
+	app := App("x", "test")
+	labels := app.StringMapOpt("L label", "labels", nil)
+	if err := app.RunArgs([]string{"-L", "os=linux", "-L", "arch=amd64"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if (*labels)["os"] != "linux" || (*labels)["arch"] != "amd64" || len(*labels) != 2 {
+		t.Fatalf("labels = %#v", *labels)
+	}
+}
+
+func TestStringMapArgAccumulates(t *testing.T) {
+	app := App("x", "test")
+	labels := app.StringMapArg("LABELS", "labels", nil)
+	if err := app.RunArgs([]string{"os=linux", "arch=amd64"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if (*labels)["os"] != "linux" || (*labels)["arch"] != "amd64" {
+		t.Fatalf("labels = %#v", *labels)
+	}
+}
+
+func TestStringMapOptMalformedEntryErrors(t *testing.T) {
+	app := App("x", "test")
+	app.StringMapOpt("L label", "labels", nil)
+	err := app.RunArgs([]string{"-L", "noequals"})
+	if err == nil {
+		t.Fatal("expected an error for a KEY=VALUE entry missing '='")
+	}
+	if _, ok := err.(*UsageError); !ok {
+		t.Fatalf("expected a *UsageError, got %T: %v", err, err)
+	}
+}
+
+func TestStringMapOptOnSubCommand(t *testing.T) {
+	app := App("x", "test")
+	var labels *map[string]string
+	app.Command("run", "", func(cmd *Cmd) {
+		labels = cmd.StringMapOpt("e env", "env vars", nil)
+	})
+	if err := app.RunArgs([]string{"run", "-e", "FOO=bar"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if (*labels)["FOO"] != "bar" {
+		t.Fatalf("labels = %#v", *labels)
+	}
+}
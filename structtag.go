@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+AppFromStruct and CommandFromStruct let callers describe an application or a
+command as a plain Go struct instead of calling the fluent BoolOpt/StringArg/
+Command API by hand. Fields are annotated with a `cli` struct tag made of
+semicolon separated `key:value` pairs:
+
+	type copyArgs struct {
+		Recursive *bool     `cli:"name:R,recursive; env:CP_RECURSIVE; desc:recursively copy"`
+		Src       *string   `cli:"arg:SRC"`
+		Dst       *string   `cli:"arg:DST"`
+	}
+
+Just like the values returned by BoolOpt, StringArg and friends, the struct
+fields are pointers: CommandFromStruct assigns them the exact pointer the
+underlying Opt/Arg constructor hands back, so they get populated the same
+way during parsing regardless of whether they were declared through the
+fluent API or through a tag.
+
+Recognized tag keys are:
+
+	name   option names (comma separated, without dashes) - presence of this key makes the field an option
+	arg    argument name - presence of this key makes the field an argument
+	env    space separated list of environment variables, forwarded to OptExtra/ArgExtra
+	desc   the option/argument description
+	spec   sets Cmd.Spec verbatim, only meaningful on the top level struct
+
+A nested (non-pointer-field) struct is treated as a sub-command: its own
+`cli` tag supplies the command name and description
+(`cli:"name:run; desc:start a container"`), and if a pointer to it implements
+`interface{ Action() }`, that method is wired up as the sub-command's Action.
+
+Supported leaf field types are *bool, *string, *int, *[]string, *[]int and
+*map[string]string, mirroring BoolOpt/StringOpt/IntOpt/StringsOpt/IntsOpt/
+StringMapOpt and their Arg counterparts (a *map[string]string field is wired
+up through StringMapOpt/StringMapArg, so its values must be KEY=VALUE on the
+command line), plus *time.Duration (wired up through VarOptDuration/
+VarArgDuration) and any other pointer type implementing
+encoding.TextUnmarshaler, wired up through VarOptText/VarArgText.
+Argument fields of type *bool or *[]int have no Arg counterpart and make
+CommandFromStruct panic.
+*/
+
+// AppFromStruct builds a new App (as returned by App) and populates it by
+// reflecting over v, which must be a pointer to a struct.
+func AppFromStruct(name, desc string, v interface{}) *Cli {
+	app := App(name, desc)
+	app.CommandFromStruct(v)
+	return app
+}
+
+// CommandFromStruct reflects over v, a pointer to a struct, and registers
+// the options, arguments and sub-commands it describes on c.
+func (c *Cmd) CommandFromStruct(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cli: CommandFromStruct needs a pointer to a struct, got %T", v))
+	}
+	structFromFields(c, rv.Elem())
+}
+
+type structTag struct {
+	names []string
+	arg   string
+	env   string
+	desc  string
+	spec  string
+}
+
+func parseStructTag(tag string) structTag {
+	var st structTag
+	for _, part := range strings.Split(tag, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		key := strings.TrimSpace(kv[0])
+		val := ""
+		if len(kv) == 2 {
+			val = strings.TrimSpace(kv[1])
+		}
+		switch key {
+		case "name":
+			for _, n := range strings.Split(val, ",") {
+				if n = strings.TrimSpace(n); n != "" {
+					st.names = append(st.names, n)
+				}
+			}
+		case "arg":
+			st.arg = val
+		case "env":
+			st.env = val
+		case "desc":
+			st.desc = val
+		case "spec":
+			st.spec = val
+		}
+	}
+	return st
+}
+
+func structFromFields(c *Cmd, sv reflect.Value) {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		tag, tagged := field.Tag.Lookup("cli")
+		fv := sv.Field(i)
+
+		if !tagged {
+			continue
+		}
+		parsed := parseStructTag(tag)
+
+		if field.Type.Kind() == reflect.Struct {
+			if len(parsed.names) == 0 {
+				panic(fmt.Sprintf("cli: sub-command field %s needs a name: in its cli tag", field.Name))
+			}
+			sub := fv.Addr().Interface()
+			c.Command(parsed.names[0], parsed.desc, func(cmd *Cmd) {
+				cmd.CommandFromStruct(sub)
+				if actioner, ok := sub.(interface{ Action() }); ok {
+					cmd.Action = actioner.Action
+				}
+			})
+			continue
+		}
+
+		if parsed.spec != "" {
+			c.Spec = parsed.spec
+		}
+		switch {
+		case len(parsed.names) > 0:
+			declareOptFromField(c, strings.Join(parsed.names, " "), parsed, fv)
+		case parsed.arg != "":
+			declareArgFromField(c, parsed.arg, parsed, fv)
+		}
+	}
+}
+
+func declareOptFromField(c *Cmd, names string, tagged structTag, fv reflect.Value) {
+	if fv.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("cli: option field for %q must be a pointer, got %s", names, fv.Type()))
+	}
+	extra := &OptExtra{EnvVar: tagged.env}
+	zero := reflect.New(fv.Type().Elem()).Elem()
+	if !fv.IsNil() {
+		zero = fv.Elem()
+	}
+	switch zero.Interface().(type) {
+	case bool:
+		fv.Set(reflect.ValueOf(c.BoolOpt(names, zero.Bool(), tagged.desc, extra)))
+	case string:
+		fv.Set(reflect.ValueOf(c.StringOpt(names, zero.String(), tagged.desc, extra)))
+	case int:
+		fv.Set(reflect.ValueOf(c.IntOpt(names, int(zero.Int()), tagged.desc, extra)))
+	case []string:
+		fv.Set(reflect.ValueOf(c.StringsOpt(names, zero.Interface().([]string), tagged.desc, extra)))
+	case []int:
+		fv.Set(reflect.ValueOf(c.IntsOpt(names, zero.Interface().([]int), tagged.desc, extra)))
+	case map[string]string:
+		fv.Set(reflect.ValueOf(c.StringMapOpt(names, tagged.desc, extra)))
+	default:
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		if p, ok := fv.Interface().(*time.Duration); ok {
+			c.VarOptDuration(names, p, tagged.desc, extra)
+			return
+		}
+		if u, ok := fv.Interface().(encoding.TextUnmarshaler); ok {
+			c.VarOptText(names, u, tagged.desc, extra)
+			return
+		}
+		panic(fmt.Sprintf("cli: unsupported option field type %s for %q", fv.Type(), names))
+	}
+}
+
+func declareArgFromField(c *Cmd, name string, tagged structTag, fv reflect.Value) {
+	if fv.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("cli: argument field for %q must be a pointer, got %s", name, fv.Type()))
+	}
+	extra := &ArgExtra{EnvVar: tagged.env}
+	zero := reflect.New(fv.Type().Elem()).Elem()
+	if !fv.IsNil() {
+		zero = fv.Elem()
+	}
+	switch zero.Interface().(type) {
+	case string:
+		fv.Set(reflect.ValueOf(c.StringArg(name, zero.String(), tagged.desc, extra)))
+	case int:
+		fv.Set(reflect.ValueOf(c.IntArg(name, int(zero.Int()), tagged.desc, extra)))
+	case []string:
+		fv.Set(reflect.ValueOf(c.StringsArg(name, zero.Interface().([]string), tagged.desc, extra)))
+	case map[string]string:
+		fv.Set(reflect.ValueOf(c.StringMapArg(name, tagged.desc, extra)))
+	default:
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		if p, ok := fv.Interface().(*time.Duration); ok {
+			c.VarArgDuration(name, p, tagged.desc, extra)
+			return
+		}
+		if u, ok := fv.Interface().(encoding.TextUnmarshaler); ok {
+			c.VarArgText(name, u, tagged.desc, extra)
+			return
+		}
+		panic(fmt.Sprintf("cli: unsupported argument field type %s for %q", fv.Type(), name))
+	}
+}
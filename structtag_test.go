@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandFromStructLeafTypes(t *testing.T) {
+	type args struct {
+		Verbose *bool              `cli:"name:v,verbose"`
+		Name    *string            `cli:"name:name"`
+		Count   *int               `cli:"name:count"`
+		Tags    *[]string          `cli:"name:tag"`
+		Labels  *map[string]string `cli:"name:L,label"`
+		Timeout *time.Duration     `cli:"name:timeout"`
+		Src     *string            `cli:"arg:SRC"`
+	}
+	var a args
+	app := App("x", "test")
+	app.CommandFromStruct(&a)
+
+	err := app.RunArgs([]string{
+		"-v",
+		"--name", "bob",
+		"--count", "3",
+		"--tag", "a", "--tag", "b",
+		"-L", "os=linux", "-L", "arch=amd64",
+		"--timeout", "2s",
+		"src.txt",
+	})
+	if err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+
+	if !*a.Verbose || *a.Name != "bob" || *a.Count != 3 {
+		t.Fatalf("unexpected scalar values: %+v %+v %+v", *a.Verbose, *a.Name, *a.Count)
+	}
+	if got, want := *a.Tags, []string{"a", "b"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Tags = %#v, want %#v", got, want)
+	}
+	if (*a.Labels)["os"] != "linux" || (*a.Labels)["arch"] != "amd64" {
+		t.Fatalf("Labels = %#v", *a.Labels)
+	}
+	if *a.Timeout != 2*time.Second {
+		t.Fatalf("Timeout = %v, want 2s", *a.Timeout)
+	}
+	if *a.Src != "src.txt" {
+		t.Fatalf("Src = %q, want %q", *a.Src, "src.txt")
+	}
+}
+
+func TestDeclareOptFromFieldUnsupportedType(t *testing.T) {
+	type args struct {
+		Bad *complex128 `cli:"name:bad"`
+	}
+	var a args
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for an unsupported option field type")
+		}
+	}()
+	App("x", "test").CommandFromStruct(&a)
+}
+
+func TestStructFromFieldsNestedSubCommand(t *testing.T) {
+	type runArgs struct {
+		Memory *string `cli:"name:m,memory"`
+	}
+	type topArgs struct {
+		Run runArgs `cli:"name:run; desc:run a container"`
+	}
+	var top topArgs
+	app := App("x", "test")
+	app.CommandFromStruct(&top)
+
+	if len(app.Commands) != 1 || app.Commands[0].Name != "run" {
+		t.Fatalf("expected one \"run\" sub-command, got %#v", app.Commands)
+	}
+	if top.Run.Memory == nil {
+		t.Fatal("expected the nested struct's field to be populated by CommandFromStruct")
+	}
+}
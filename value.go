@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"encoding"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Value is the interface a custom option/argument type implements to be
+// usable with VarOpt/VarArg. It is deliberately the same shape as the
+// standard library's flag.Value, so a flag.Value implementation (a
+// time.Duration wrapper, a net.IP, a validating enum, ...) can be used with
+// VarOpt/VarArg unchanged.
+type Value interface {
+	Set(string) error
+	String() string
+}
+
+// Placeholder is an optional interface a Value can implement to control the
+// meta-var PrintHelp shows for a VarOpt option (e.g. "DURATION") instead of
+// falling back to the option's own name; arguments declared with VarArg are
+// still listed under their own name. durationValue implements it; a custom
+// Value can too, when its own String()/flag.Value-shaped name isn't a good
+// fit for help output.
+type Placeholder interface {
+	Placeholder() string
+}
+
+// BoolFlag is an optional interface a Value can implement (mirroring the
+// standard library flag package's unexported boolFlag) to be treated as a
+// boolean option: settable without an explicit value (-x rather than
+// -x=v), rather than requiring one like other VarOpt values do.
+type BoolFlag interface {
+	IsBoolFlag() bool
+}
+
+// VarOpt declares an option backed by a custom Value, for types mow.cli
+// doesn't know how to parse natively (URLs, time.Duration, net.IP,
+// validating enums, ...). If value also implements BoolFlag and
+// IsBoolFlag() returns true, the option behaves like a boolean flag (no
+// explicit value required on the command line); otherwise it behaves like
+// a StringOpt whose raw value is handed to value.Set.
+func (c *Cmd) VarOpt(names string, value Value, desc string, extra *OptExtra) {
+	if bf, ok := value.(BoolFlag); ok && bf.IsBoolFlag() {
+		def, _ := strconv.ParseBool(value.String())
+		raw := c.BoolOpt(names, def, desc, extra)
+		c.varSyncs = append(c.varSyncs, func() error {
+			if *raw {
+				return value.Set("true")
+			}
+			return nil
+		})
+		return
+	}
+	raw := c.StringOpt(names, value.String(), desc, extra)
+	if p, ok := value.(Placeholder); ok {
+		c.lastOpt().metaVar = p.Placeholder()
+	}
+	c.varSyncs = append(c.varSyncs, func() error {
+		return value.Set(*raw)
+	})
+}
+
+// VarOpt is the App-level equivalent of Cmd.VarOpt, for global options.
+func (app *Cli) VarOpt(names string, value Value, desc string, extra *OptExtra) {
+	app.Cmd.VarOpt(names, value, desc, extra)
+}
+
+// VarArg is the argument equivalent of VarOpt.
+func (c *Cmd) VarArg(name string, value Value, desc string, extra *ArgExtra) {
+	raw := c.StringArg(name, value.String(), desc, extra)
+	if p, ok := value.(Placeholder); ok {
+		c.lastArg().metaVar = p.Placeholder()
+	}
+	c.varSyncs = append(c.varSyncs, func() error {
+		return value.Set(*raw)
+	})
+}
+
+// syncVars applies every pending Value.Set call registered by VarOpt/VarArg
+// anywhere in c's tree, stopping at (and returning) the first error. Like
+// syncStringMaps, RunArgs calls this right after a successful parse and
+// before dispatching to the selected command's Action/ActionContext.
+func syncVars(c *Cmd) error {
+	for _, sync := range c.varSyncs {
+		if err := sync(); err != nil {
+			return err
+		}
+	}
+	for _, sub := range c.Commands {
+		if err := syncVars(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textValue adapts any encoding.TextUnmarshaler into a Value, so types that
+// only bother implementing the stdlib text marshalling interfaces (rather
+// than flag.Value) can still be used with VarOpt/VarArg.
+type textValue struct {
+	u encoding.TextUnmarshaler
+}
+
+func (t textValue) Set(s string) error { return t.u.UnmarshalText([]byte(s)) }
+
+func (t textValue) String() string {
+	if s, ok := t.u.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return ""
+}
+
+// durationValue adapts a *time.Duration into a Value: the standard library's
+// time.Duration does not implement encoding.TextUnmarshaler (it has no
+// UnmarshalText method), so it can't go through textValue the way other
+// stdlib-adjacent types can - this parses with time.ParseDuration instead.
+type durationValue struct{ p *time.Duration }
+
+func (d durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d.p = v
+	return nil
+}
+
+func (d durationValue) String() string { return d.p.String() }
+
+// Placeholder makes durationValue satisfy Placeholder, so VarOptDuration
+// options show "DURATION" (e.g. "1h30m", "90s") rather than their option
+// name as their help meta-var.
+func (d durationValue) Placeholder() string { return "DURATION" }
+
+// VarOptDuration is the time.Duration convenience overload of VarOpt.
+func (c *Cmd) VarOptDuration(names string, p *time.Duration, desc string, extra *OptExtra) {
+	c.VarOpt(names, durationValue{p}, desc, extra)
+}
+
+// VarArgDuration is the time.Duration convenience overload of VarArg.
+func (c *Cmd) VarArgDuration(name string, p *time.Duration, desc string, extra *ArgExtra) {
+	c.VarArg(name, durationValue{p}, desc, extra)
+}
+
+// VarOptText is the encoding.TextUnmarshaler convenience overload of
+// VarOpt: u is wrapped in a Value that calls UnmarshalText, so a type only
+// implementing the stdlib text-marshalling interfaces doesn't also need a
+// hand-written Value.
+func (c *Cmd) VarOptText(names string, u encoding.TextUnmarshaler, desc string, extra *OptExtra) {
+	c.VarOpt(names, textValue{u}, desc, extra)
+}
+
+// VarArgText is the encoding.TextUnmarshaler convenience overload of VarArg.
+func (c *Cmd) VarArgText(name string, u encoding.TextUnmarshaler, desc string, extra *ArgExtra) {
+	c.VarArg(name, textValue{u}, desc, extra)
+}
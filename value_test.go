@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+type upperValue struct{ s string }
+
+func (u *upperValue) Set(s string) error {
+	u.s = s
+	return nil
+}
+func (u *upperValue) String() string { return u.s }
+
+func TestVarOptCustomValue(t *testing.T) {
+	app := App("x", "test")
+	v := &upperValue{}
+	app.VarOpt("u upper", v, "", nil)
+	if err := app.RunArgs([]string{"-u", "hello"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if v.s != "hello" {
+		t.Fatalf("v.s = %q, want %q", v.s, "hello")
+	}
+}
+
+type boolFlagValue struct{ set bool }
+
+func (b *boolFlagValue) Set(string) error { b.set = true; return nil }
+func (b *boolFlagValue) String() string   { return "false" }
+func (b *boolFlagValue) IsBoolFlag() bool { return true }
+
+func TestVarOptBoolFlag(t *testing.T) {
+	app := App("x", "test")
+	v := &boolFlagValue{}
+	app.VarOpt("f force", v, "", nil)
+	if err := app.RunArgs([]string{"-f"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if !v.set {
+		t.Fatal("expected the bool-flag Value to have been Set")
+	}
+}
+
+func TestVarArgCustomValue(t *testing.T) {
+	app := App("x", "test")
+	v := &upperValue{}
+	app.VarArg("VAL", v, "", nil)
+	if err := app.RunArgs([]string{"hello"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if v.s != "hello" {
+		t.Fatalf("v.s = %q, want %q", v.s, "hello")
+	}
+}
+
+func TestVarOptDuration(t *testing.T) {
+	app := App("x", "test")
+	var d time.Duration
+	app.VarOptDuration("t timeout", &d, "", nil)
+	if err := app.RunArgs([]string{"-t", "90s"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if d != 90*time.Second {
+		t.Fatalf("d = %v, want 90s", d)
+	}
+}
+
+func TestVarOptDurationInvalidValue(t *testing.T) {
+	app := App("x", "test")
+	var d time.Duration
+	app.VarOptDuration("t timeout", &d, "", nil)
+	if err := app.RunArgs([]string{"-t", "not-a-duration"}); err == nil {
+		t.Fatal("expected an error for an invalid duration")
+	}
+}
+
+type upperText struct{ s string }
+
+func (u *upperText) UnmarshalText(b []byte) error {
+	u.s = string(b)
+	return nil
+}
+
+func TestVarOptText(t *testing.T) {
+	app := App("x", "test")
+	v := &upperText{}
+	app.VarOptText("n name", v, "", nil)
+	if err := app.RunArgs([]string{"-n", "bob"}); err != nil {
+		t.Fatalf("RunArgs: unexpected error: %v", err)
+	}
+	if v.s != "bob" {
+		t.Fatalf("v.s = %q, want %q", v.s, "bob")
+	}
+}